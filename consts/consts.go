@@ -0,0 +1,6 @@
+package consts
+
+// UnknownArtist is the placeholder artist name used for tracks whose real
+// artist could not be determined during scan. Scrobbling/NowPlaying is
+// skipped for such tracks since there is nothing useful to report upstream.
+const UnknownArtist = "[Unknown Artist]"
@@ -0,0 +1,97 @@
+package scrobbler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// Errors that agents can return from Scrobble to tell the offline queue
+// (see buffer.go) that a submission must not be retried: ErrUnauthorized
+// means the user has revoked/never granted access, ErrUnknownTrack means
+// the remote service rejected the track itself. Any other error is treated
+// as transient and queued for retry with backoff.
+var (
+	ErrUnauthorized = errors.New("scrobbler: user not authorized")
+	ErrUnknownTrack = errors.New("scrobbler: track not recognized by agent")
+)
+
+// Scrobble represents a single play submission, as reported by a client.
+type Scrobble struct {
+	model.MediaFile
+	TimeStamp time.Time
+}
+
+// PlayMetadata carries the additional identifiers agents need to match a
+// track against external catalogs (MusicBrainz, ISRC-based services, etc.)
+// without resorting to fuzzy string matching on title/artist/album.
+//
+// It is assembled once per NowPlaying/Submit call by playTracker, from the
+// MediaFile, its Album and its participant artists, and handed to every
+// registered agent so they don't each have to re-derive it.
+type PlayMetadata struct {
+	MbzRecordingID    string
+	MbzReleaseID      string
+	MbzReleaseGroupID string
+	MbzAlbumArtistID  string
+	MbzArtistIDs      []string
+	ISRC              string
+	TrackNumber       int
+	DurationSeconds   float32
+	MediaType         string
+}
+
+// Scrobbler is implemented by agents that can receive "now playing" and
+// scrobble (play submission) notifications, such as Last.fm or ListenBrainz.
+type Scrobbler interface {
+	IsAuthorized(ctx context.Context, userId string) bool
+	NowPlaying(ctx context.Context, userId string, track *model.MediaFile) error
+	Scrobble(ctx context.Context, userId string, s Scrobble) error
+}
+
+// MetadataScrobbler is an optional extension of Scrobbler. Agents that want
+// access to the richer PlayMetadata payload (MBIDs, ISRCs, etc.) implement
+// it alongside Scrobbler; playTracker falls back to the plain Scrobbler
+// methods for agents that don't.
+type MetadataScrobbler interface {
+	NowPlayingWithMetadata(ctx context.Context, userId string, track *model.MediaFile, md PlayMetadata) error
+	ScrobbleWithMetadata(ctx context.Context, userId string, s Scrobble, md PlayMetadata) error
+}
+
+// Constructor creates a new Scrobbler instance backed by the given
+// DataStore. It should return nil if the agent is not configured/enabled.
+type Constructor func(ds model.DataStore) Scrobbler
+
+var constructors = struct {
+	sync.RWMutex
+	m map[string]Constructor
+}{m: map[string]Constructor{}}
+
+// Register registers a Scrobbler agent constructor under the given name, so
+// it can be picked up by newPlayTracker. Meant to be called from an init()
+// function in the agent's package.
+func Register(name string, constructor Constructor) {
+	constructors.Lock()
+	defer constructors.Unlock()
+	constructors.m[name] = constructor
+}
+
+func createScrobblers(ds model.DataStore) map[string]Scrobbler {
+	constructors.RLock()
+	defer constructors.RUnlock()
+
+	agents := make(map[string]Scrobbler, len(constructors.m))
+	for name, constructor := range constructors.m {
+		agent := constructor(ds)
+		if agent == nil {
+			log.Debug("Scrobbler agent disabled", "agent", name)
+			continue
+		}
+		agents[name] = newBufferedScrobbler(ds, agent, name)
+	}
+	return agents
+}
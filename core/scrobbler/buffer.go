@@ -0,0 +1,196 @@
+package scrobbler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+const (
+	retryPollInterval = 30 * time.Second
+	retryBaseDelay    = 1 * time.Minute
+	retryMaxDelay     = 6 * time.Hour
+	retryBatchSize    = 20
+)
+
+// bufferedScrobbler wraps a Scrobbler agent so that a transient failure in
+// Scrobble (network error, 5xx, rate-limiting) does not drop the play: it is
+// persisted to the scrobble_queue table and retried with exponential
+// backoff by a background worker, instead of being silently lost. NowPlaying
+// is best-effort and is never queued, since a stale "now playing" heartbeat
+// is not worth retrying.
+type bufferedScrobbler struct {
+	name  string
+	agent Scrobbler
+	ds    model.DataStore
+}
+
+func newBufferedScrobbler(ds model.DataStore, agent Scrobbler, name string) Scrobbler {
+	return &bufferedScrobbler{name: name, agent: agent, ds: ds}
+}
+
+func (b *bufferedScrobbler) IsAuthorized(ctx context.Context, userId string) bool {
+	return b.agent.IsAuthorized(ctx, userId)
+}
+
+func (b *bufferedScrobbler) NowPlaying(ctx context.Context, userId string, track *model.MediaFile) error {
+	return b.agent.NowPlaying(ctx, userId, track)
+}
+
+func (b *bufferedScrobbler) NowPlayingWithMetadata(ctx context.Context, userId string, track *model.MediaFile, md PlayMetadata) error {
+	if ms, ok := b.agent.(MetadataScrobbler); ok {
+		return ms.NowPlayingWithMetadata(ctx, userId, track, md)
+	}
+	return b.agent.NowPlaying(ctx, userId, track)
+}
+
+func (b *bufferedScrobbler) Scrobble(ctx context.Context, userId string, s Scrobble) error {
+	err := b.agent.Scrobble(ctx, userId, s)
+	return b.handleResult(ctx, userId, s.ID, s.TimeStamp, err)
+}
+
+func (b *bufferedScrobbler) ScrobbleWithMetadata(ctx context.Context, userId string, s Scrobble, md PlayMetadata) error {
+	var err error
+	if ms, ok := b.agent.(MetadataScrobbler); ok {
+		err = ms.ScrobbleWithMetadata(ctx, userId, s, md)
+	} else {
+		err = b.agent.Scrobble(ctx, userId, s)
+	}
+	return b.handleResult(ctx, userId, s.ID, s.TimeStamp, err)
+}
+
+func (b *bufferedScrobbler) handleResult(ctx context.Context, userId, trackId string, playTime time.Time, err error) error {
+	if err == nil {
+		return nil
+	}
+	if isPermanentFailure(err) {
+		log.Warn(ctx, "Scrobble rejected permanently, not queueing", "agent", b.name, "track", trackId, err)
+		return err
+	}
+
+	entry := &model.ScrobbleEntry{
+		ID:          uuid.NewString(),
+		UserID:      userId,
+		Agent:       b.name,
+		TrackID:     trackId,
+		PlayTime:    playTime,
+		EnqueuedAt:  time.Now(),
+		NextRetryAt: time.Now().Add(retryBaseDelay),
+	}
+	if qErr := b.ds.ScrobbleBuffer(ctx).Enqueue(entry); qErr != nil {
+		log.Error(ctx, "Error queueing failed scrobble for retry", "agent", b.name, "track", trackId, qErr)
+		return err
+	}
+	log.Warn(ctx, "Scrobble failed, queued for retry", "agent", b.name, "track", trackId, err)
+	return nil
+}
+
+func isPermanentFailure(err error) bool {
+	return errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrUnknownTrack)
+}
+
+// enqueueOffline queues an offline-recorded play directly, for every
+// authorized agent, without attempting immediate delivery. Used by Submit
+// when the client explicitly flags a Submission as offline: the server
+// defers transmission instead of trying (and likely failing) to send it
+// right away.
+func (p *playTracker) enqueueOffline(ctx context.Context, userId string, mf *model.MediaFile, playTime time.Time) {
+	for name, agent := range p.scrobblers {
+		if !agent.IsAuthorized(ctx, userId) {
+			continue
+		}
+		entry := &model.ScrobbleEntry{
+			ID:          uuid.NewString(),
+			UserID:      userId,
+			Agent:       name,
+			TrackID:     mf.ID,
+			PlayTime:    playTime,
+			EnqueuedAt:  time.Now(),
+			NextRetryAt: time.Now(),
+		}
+		if err := p.ds.ScrobbleBuffer(ctx).Enqueue(entry); err != nil {
+			log.Error(ctx, "Error queueing offline scrobble", "agent", name, "track", mf.ID, err)
+		}
+	}
+}
+
+// startQueueWorker periodically drains the offline scrobble queue for every
+// registered agent, retrying due entries and backing off exponentially on
+// repeated failure. It runs for the lifetime of the playTracker.
+func (p *playTracker) startQueueWorker(ctx context.Context) {
+	ticker := time.NewTicker(retryPollInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.drainQueue(ctx)
+			}
+		}
+	}()
+}
+
+func (p *playTracker) drainQueue(ctx context.Context) {
+	for name, agent := range p.scrobblers {
+		buffered, ok := agent.(*bufferedScrobbler)
+		if !ok {
+			continue
+		}
+		entries, err := p.ds.ScrobbleBuffer(ctx).Next(name, retryBatchSize)
+		if err != nil {
+			log.Error(ctx, "Error reading scrobble queue", "agent", name, err)
+			continue
+		}
+		for _, entry := range entries {
+			p.retryEntry(ctx, buffered, entry)
+		}
+	}
+}
+
+func (p *playTracker) retryEntry(ctx context.Context, buffered *bufferedScrobbler, entry *model.ScrobbleEntry) {
+	if !buffered.agent.IsAuthorized(ctx, entry.UserID) {
+		log.Debug(ctx, "Dropping queued scrobble, user no longer authorized", "agent", entry.Agent, "track", entry.TrackID)
+		_ = p.ds.ScrobbleBuffer(ctx).Dequeue(entry.ID)
+		return
+	}
+
+	mf, err := p.ds.MediaFile(ctx).Get(entry.TrackID)
+	if err != nil {
+		log.Warn(ctx, "Dropping queued scrobble, track no longer exists", "agent", entry.Agent, "track", entry.TrackID, err)
+		_ = p.ds.ScrobbleBuffer(ctx).Dequeue(entry.ID)
+		return
+	}
+
+	md := p.buildPlayMetadata(ctx, mf)
+	scrobble := Scrobble{MediaFile: *mf, TimeStamp: entry.PlayTime}
+	var scrobbleErr error
+	if ms, ok := buffered.agent.(MetadataScrobbler); ok {
+		scrobbleErr = ms.ScrobbleWithMetadata(ctx, entry.UserID, scrobble, md)
+	} else {
+		scrobbleErr = buffered.agent.Scrobble(ctx, entry.UserID, scrobble)
+	}
+
+	if scrobbleErr == nil {
+		_ = p.ds.ScrobbleBuffer(ctx).Dequeue(entry.ID)
+		return
+	}
+	if isPermanentFailure(scrobbleErr) {
+		log.Warn(ctx, "Queued scrobble rejected permanently, dropping", "agent", entry.Agent, "track", entry.TrackID, scrobbleErr)
+		_ = p.ds.ScrobbleBuffer(ctx).Dequeue(entry.ID)
+		return
+	}
+
+	delay := retryBaseDelay << entry.Retries
+	if delay > retryMaxDelay || delay <= 0 {
+		delay = retryMaxDelay
+	}
+	if err := p.ds.ScrobbleBuffer(ctx).ScheduleRetry(entry.ID, time.Now().Add(delay), scrobbleErr.Error()); err != nil {
+		log.Error(ctx, "Error rescheduling queued scrobble", "agent", entry.Agent, "track", entry.TrackID, err)
+	}
+}
@@ -0,0 +1,150 @@
+package scrobbler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	"github.com/navidrome/navidrome/tests"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("bufferedScrobbler", func() {
+	var ctx context.Context
+	var ds model.DataStore
+	var fake fakeScrobbler
+	var buffered *bufferedScrobbler
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctx = request.WithUser(ctx, model.User{ID: "u-1"})
+		ds = &tests.MockDataStore{}
+		fake = fakeScrobbler{Authorized: true}
+		buffered = &bufferedScrobbler{name: "fake", agent: &fake, ds: ds}
+	})
+
+	Describe("isPermanentFailure", func() {
+		It("treats ErrUnauthorized as permanent", func() {
+			Expect(isPermanentFailure(ErrUnauthorized)).To(BeTrue())
+		})
+		It("treats ErrUnknownTrack as permanent", func() {
+			Expect(isPermanentFailure(ErrUnknownTrack)).To(BeTrue())
+		})
+		It("treats any other error as transient", func() {
+			Expect(isPermanentFailure(errors.New("connection reset"))).To(BeFalse())
+		})
+	})
+
+	Describe("Scrobble", func() {
+		It("does not queue anything on success", func() {
+			err := buffered.Scrobble(ctx, "u-1", Scrobble{MediaFile: model.MediaFile{ID: "t-1"}})
+
+			Expect(err).ToNot(HaveOccurred())
+			length, _ := ds.ScrobbleBuffer(ctx).Length("fake")
+			Expect(length).To(Equal(int64(0)))
+		})
+
+		It("queues the scrobble for retry on a transient error", func() {
+			fake.Error = errors.New("connection reset")
+
+			err := buffered.Scrobble(ctx, "u-1", Scrobble{MediaFile: model.MediaFile{ID: "t-1"}})
+
+			Expect(err).ToNot(HaveOccurred())
+			length, _ := ds.ScrobbleBuffer(ctx).Length("fake")
+			Expect(length).To(Equal(int64(1)))
+		})
+
+		It("does not queue and returns the error on a permanent failure", func() {
+			fake.Error = ErrUnauthorized
+
+			err := buffered.Scrobble(ctx, "u-1", Scrobble{MediaFile: model.MediaFile{ID: "t-1"}})
+
+			Expect(err).To(Equal(ErrUnauthorized))
+			length, _ := ds.ScrobbleBuffer(ctx).Length("fake")
+			Expect(length).To(Equal(int64(0)))
+		})
+	})
+})
+
+var _ = Describe("playTracker queue worker", func() {
+	var ctx context.Context
+	var ds model.DataStore
+	var fake retryFakeScrobbler
+	var buffered *bufferedScrobbler
+	var p *playTracker
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ds = &tests.MockDataStore{}
+		track := model.MediaFile{ID: "t-1", Artist: "Artist"}
+		_ = ds.MediaFile(ctx).Put(&track)
+		fake = retryFakeScrobbler{Authorized: true}
+		buffered = &bufferedScrobbler{name: "fake", agent: &fake, ds: ds}
+		p = &playTracker{ds: ds, scrobblers: map[string]Scrobbler{"fake": buffered}}
+	})
+
+	It("removes the entry from the queue once it succeeds", func() {
+		entry := &model.ScrobbleEntry{ID: "e-1", UserID: "u-1", Agent: "fake", TrackID: "t-1", PlayTime: time.Now()}
+		_ = ds.ScrobbleBuffer(ctx).Enqueue(entry)
+
+		p.retryEntry(ctx, buffered, entry)
+
+		Expect(fake.ScrobbleCalled).To(BeTrue())
+		length, _ := ds.ScrobbleBuffer(ctx).Length("fake")
+		Expect(length).To(Equal(int64(0)))
+	})
+
+	It("backs off exponentially on repeated transient failure", func() {
+		fake.Error = errors.New("connection reset")
+		entry := &model.ScrobbleEntry{ID: "e-1", UserID: "u-1", Agent: "fake", TrackID: "t-1", PlayTime: time.Now(), Retries: 2}
+		_ = ds.ScrobbleBuffer(ctx).Enqueue(entry)
+		before := time.Now()
+
+		p.retryEntry(ctx, buffered, entry)
+
+		length, _ := ds.ScrobbleBuffer(ctx).Length("fake")
+		Expect(length).To(Equal(int64(1)))
+		Expect(entry.Retries).To(Equal(3))
+		Expect(entry.NextRetryAt).To(BeTemporally(">", before.Add(retryBaseDelay<<2)))
+	})
+
+	It("drops the entry on a permanent failure", func() {
+		fake.Error = ErrUnauthorized
+		entry := &model.ScrobbleEntry{ID: "e-1", UserID: "u-1", Agent: "fake", TrackID: "t-1", PlayTime: time.Now()}
+		_ = ds.ScrobbleBuffer(ctx).Enqueue(entry)
+
+		p.retryEntry(ctx, buffered, entry)
+
+		length, _ := ds.ScrobbleBuffer(ctx).Length("fake")
+		Expect(length).To(Equal(int64(0)))
+	})
+})
+
+// retryFakeScrobbler is used by the queue-worker specs instead of the
+// shared fakeScrobbler: fakeScrobbler.IsAuthorized reports unauthorized
+// whenever Error is set (so other specs can simulate "revoked access"
+// with a single field), which is wrong here - these specs set Error to
+// simulate a transient Scrobble failure, and retryEntry must still see
+// the user as authorized so it reschedules the entry instead of dropping
+// it as permanently unauthorized.
+type retryFakeScrobbler struct {
+	Authorized     bool
+	ScrobbleCalled bool
+	Error          error
+}
+
+func (f *retryFakeScrobbler) IsAuthorized(ctx context.Context, userId string) bool {
+	return f.Authorized
+}
+
+func (f *retryFakeScrobbler) NowPlaying(ctx context.Context, userId string, track *model.MediaFile) error {
+	return nil
+}
+
+func (f *retryFakeScrobbler) Scrobble(ctx context.Context, userId string, s Scrobble) error {
+	f.ScrobbleCalled = true
+	return f.Error
+}
@@ -0,0 +1,194 @@
+package scrobbler
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/navidrome/navidrome/consts"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+)
+
+// FilterConfig describes the scrobble filter rules evaluated by playTracker
+// before a NowPlaying/Submit is dispatched to agents. PlayerOverrides lets a
+// specific player (keyed by its name) replace any subset of the global
+// rules, e.g. a car stereo that should never be held to the minimum-play
+// duration rule. UserOverrides does the same thing keyed by username, for
+// per-user preferences; when both a player and a user override apply to the
+// same field, the user override wins.
+type FilterConfig struct {
+	MinPlayDuration   time.Duration
+	MinPlayPercentage float64
+	MaxPlayDuration   time.Duration
+	GenreDenyList     []string
+	GenreAllowList    []string
+	LibraryDenyList   []string
+	PlayerOverrides   map[string]FilterConfig
+	UserOverrides     map[string]FilterConfig
+}
+
+// DefaultFilterConfig is used by playTracker until SetFilterConfig is called
+// to install the rules loaded from the server's configuration and any
+// per-user preferences.
+var DefaultFilterConfig = FilterConfig{
+	MinPlayDuration:   30 * time.Second,
+	MinPlayPercentage: 0.5,
+}
+
+var filterConfig = struct {
+	sync.RWMutex
+	cfg FilterConfig
+}{cfg: DefaultFilterConfig}
+
+// SetFilterConfig replaces the rules playTracker evaluates against. Meant to
+// be called once at startup, and again whenever the scrobbler config or a
+// user's filter preferences change, so every playTracker instance picks up
+// the new rules.
+func SetFilterConfig(cfg FilterConfig) {
+	filterConfig.Lock()
+	defer filterConfig.Unlock()
+	filterConfig.cfg = cfg
+}
+
+func currentFilterConfig() FilterConfig {
+	filterConfig.RLock()
+	defer filterConfig.RUnlock()
+	return filterConfig.cfg
+}
+
+func effectiveFilterConfig(playerName, username string) FilterConfig {
+	cfg := currentFilterConfig()
+	if override, ok := cfg.PlayerOverrides[playerName]; ok {
+		cfg = mergeFilterConfig(cfg, override)
+	}
+	if override, ok := cfg.UserOverrides[username]; ok {
+		cfg = mergeFilterConfig(cfg, override)
+	}
+	return cfg
+}
+
+// mergeFilterConfig returns base with every non-zero field of override
+// applied on top of it.
+func mergeFilterConfig(base, override FilterConfig) FilterConfig {
+	if override.MinPlayDuration != 0 {
+		base.MinPlayDuration = override.MinPlayDuration
+	}
+	if override.MinPlayPercentage != 0 {
+		base.MinPlayPercentage = override.MinPlayPercentage
+	}
+	if override.MaxPlayDuration != 0 {
+		base.MaxPlayDuration = override.MaxPlayDuration
+	}
+	if override.GenreDenyList != nil {
+		base.GenreDenyList = override.GenreDenyList
+	}
+	if override.GenreAllowList != nil {
+		base.GenreAllowList = override.GenreAllowList
+	}
+	if override.LibraryDenyList != nil {
+		base.LibraryDenyList = override.LibraryDenyList
+	}
+	return base
+}
+
+// FilterDecision records whether a track was let through to agents, and why
+// not when it wasn't.
+type FilterDecision struct {
+	Allowed bool
+	Reason  string
+}
+
+// FilterRecord is a FilterDecision with enough context (who, what, when) to
+// answer "why didn't this get scrobbled", surfaced through
+// PlayTracker.RecentFilterDecisions for an admin API to inspect.
+type FilterRecord struct {
+	Timestamp time.Time
+	UserID    string
+	PlayerId  string
+	TrackID   string
+	Allowed   bool
+	Reason    string
+}
+
+const filterHistoryLimit = 200
+
+func (p *playTracker) recordFilterDecision(rec FilterRecord) {
+	p.filterMu.Lock()
+	defer p.filterMu.Unlock()
+	p.filterHistory = append(p.filterHistory, rec)
+	if len(p.filterHistory) > filterHistoryLimit {
+		p.filterHistory = p.filterHistory[len(p.filterHistory)-filterHistoryLimit:]
+	}
+}
+
+func (p *playTracker) RecentFilterDecisions() []FilterRecord {
+	p.filterMu.Lock()
+	defer p.filterMu.Unlock()
+	out := make([]FilterRecord, len(p.filterHistory))
+	copy(out, p.filterHistory)
+	return out
+}
+
+// evaluate decides whether mf should be dispatched to scrobbler agents.
+// elapsed is how long the client says it actually played the track; pass 0
+// when it is not known (e.g. a NowPlaying heartbeat), which skips the
+// minimum-duration rule.
+func (p *playTracker) evaluate(ctx context.Context, mf *model.MediaFile, elapsed time.Duration) FilterDecision {
+	player, _ := request.PlayerFrom(ctx)
+	if !player.ScrobbleEnabled {
+		return FilterDecision{Reason: "player_scrobbling_disabled"}
+	}
+	if mf.Artist == consts.UnknownArtist {
+		return FilterDecision{Reason: "unknown_artist"}
+	}
+
+	u, _ := request.UserFrom(ctx)
+	cfg := effectiveFilterConfig(player.Name, u.UserName)
+	if stringInList(mf.Genre, cfg.GenreDenyList) {
+		return FilterDecision{Reason: "genre_denied"}
+	}
+	if len(cfg.GenreAllowList) > 0 && !stringInList(mf.Genre, cfg.GenreAllowList) {
+		return FilterDecision{Reason: "genre_not_allowed"}
+	}
+	if intInList(mf.LibraryID, cfg.LibraryDenyList) {
+		return FilterDecision{Reason: "library_denied"}
+	}
+
+	trackDuration := time.Duration(mf.Duration * float32(time.Second))
+	if cfg.MaxPlayDuration > 0 && trackDuration > cfg.MaxPlayDuration {
+		return FilterDecision{Reason: "track_too_long"}
+	}
+
+	if elapsed > 0 {
+		threshold := cfg.MinPlayDuration
+		if cfg.MinPlayPercentage > 0 {
+			pct := time.Duration(float64(trackDuration) * cfg.MinPlayPercentage)
+			if threshold == 0 || pct < threshold {
+				threshold = pct
+			}
+		}
+		if threshold > 0 && elapsed < threshold {
+			return FilterDecision{Reason: "played_too_short"}
+		}
+	}
+
+	return FilterDecision{Allowed: true}
+}
+
+func stringInList(value string, list []string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func intInList(value int, list []string) bool {
+	if len(list) == 0 {
+		return false
+	}
+	return stringInList(strconv.Itoa(value), list)
+}
@@ -0,0 +1,21 @@
+package scrobbler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/navidrome/navidrome/log"
+)
+
+// FilterDecisionsHandler returns an http.Handler that serves the most
+// recent scrobble filter decisions as JSON, so an admin can inspect why a
+// given submission was (or wasn't) scrobbled. Meant to be mounted under the
+// server's admin-only routes.
+func FilterDecisionsHandler(tracker PlayTracker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(tracker.RecentFilterDecisions()); err != nil {
+			log.Error(r.Context(), "Error encoding filter decisions", err)
+		}
+	})
+}
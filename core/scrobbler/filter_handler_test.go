@@ -0,0 +1,34 @@
+package scrobbler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type fakePlayTracker struct {
+	PlayTracker
+	decisions []FilterRecord
+}
+
+func (f *fakePlayTracker) RecentFilterDecisions() []FilterRecord {
+	return f.decisions
+}
+
+var _ = Describe("FilterDecisionsHandler", func() {
+	It("serves the tracker's recent filter decisions as JSON", func() {
+		tracker := &fakePlayTracker{decisions: []FilterRecord{{TrackID: "t-1", Allowed: false, Reason: "genre_denied"}}}
+		req := httptest.NewRequest(http.MethodGet, "/filter-decisions", nil)
+		rec := httptest.NewRecorder()
+
+		FilterDecisionsHandler(tracker).ServeHTTP(rec, req)
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		var got []FilterRecord
+		Expect(json.Unmarshal(rec.Body.Bytes(), &got)).To(Succeed())
+		Expect(got).To(Equal(tracker.decisions))
+	})
+})
@@ -0,0 +1,132 @@
+package scrobbler
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("playTracker.evaluate", func() {
+	var ctx context.Context
+	var p *playTracker
+	var track model.MediaFile
+
+	BeforeEach(func() {
+		ctx = request.WithPlayer(context.Background(), model.Player{Name: "player-1", ScrobbleEnabled: true})
+		ctx = request.WithUser(ctx, model.User{UserName: "user-1"})
+		p = &playTracker{}
+		track = model.MediaFile{ID: "t-1", Artist: "Some Artist", Genre: "Rock", Duration: 200}
+		SetFilterConfig(DefaultFilterConfig)
+	})
+
+	It("allows a track that passes every rule", func() {
+		decision := p.evaluate(ctx, &track, 0)
+		Expect(decision.Allowed).To(BeTrue())
+	})
+
+	It("denies a track whose genre is in the deny list", func() {
+		SetFilterConfig(FilterConfig{GenreDenyList: []string{"Rock"}})
+
+		decision := p.evaluate(ctx, &track, 0)
+
+		Expect(decision.Allowed).To(BeFalse())
+		Expect(decision.Reason).To(Equal("genre_denied"))
+	})
+
+	It("denies a track whose genre is not in the allow list", func() {
+		SetFilterConfig(FilterConfig{GenreAllowList: []string{"Jazz"}})
+
+		decision := p.evaluate(ctx, &track, 0)
+
+		Expect(decision.Allowed).To(BeFalse())
+		Expect(decision.Reason).To(Equal("genre_not_allowed"))
+	})
+
+	It("denies a track from a denied library", func() {
+		track.LibraryID = 42
+		SetFilterConfig(FilterConfig{LibraryDenyList: []string{strconv.Itoa(42)}})
+
+		decision := p.evaluate(ctx, &track, 0)
+
+		Expect(decision.Allowed).To(BeFalse())
+		Expect(decision.Reason).To(Equal("library_denied"))
+	})
+
+	It("denies a track longer than the configured maximum", func() {
+		SetFilterConfig(FilterConfig{MaxPlayDuration: 100 * time.Second})
+
+		decision := p.evaluate(ctx, &track, 0)
+
+		Expect(decision.Allowed).To(BeFalse())
+		Expect(decision.Reason).To(Equal("track_too_long"))
+	})
+
+	It("denies a submission played for less than the minimum duration", func() {
+		SetFilterConfig(FilterConfig{MinPlayDuration: 60 * time.Second})
+
+		decision := p.evaluate(ctx, &track, 10*time.Second)
+
+		Expect(decision.Allowed).To(BeFalse())
+		Expect(decision.Reason).To(Equal("played_too_short"))
+	})
+
+	It("allows a submission played past the minimum duration", func() {
+		SetFilterConfig(FilterConfig{MinPlayDuration: 60 * time.Second})
+
+		decision := p.evaluate(ctx, &track, 90*time.Second)
+
+		Expect(decision.Allowed).To(BeTrue())
+	})
+
+	It("denies a track when the player is not enabled to scrobble", func() {
+		ctx = request.WithPlayer(ctx, model.Player{Name: "player-1", ScrobbleEnabled: false})
+
+		decision := p.evaluate(ctx, &track, 0)
+
+		Expect(decision.Allowed).To(BeFalse())
+		Expect(decision.Reason).To(Equal("player_scrobbling_disabled"))
+	})
+
+	It("applies a player override on top of the global config", func() {
+		SetFilterConfig(FilterConfig{
+			MinPlayDuration: 60 * time.Second,
+			PlayerOverrides: map[string]FilterConfig{
+				"player-1": {MinPlayDuration: 5 * time.Second},
+			},
+		})
+
+		decision := p.evaluate(ctx, &track, 10*time.Second)
+
+		Expect(decision.Allowed).To(BeTrue())
+	})
+
+	It("applies a user override on top of a player override", func() {
+		SetFilterConfig(FilterConfig{
+			MinPlayDuration: 60 * time.Second,
+			PlayerOverrides: map[string]FilterConfig{
+				"player-1": {MinPlayDuration: 5 * time.Second},
+			},
+			UserOverrides: map[string]FilterConfig{
+				"user-1": {MinPlayDuration: 120 * time.Second},
+			},
+		})
+
+		decision := p.evaluate(ctx, &track, 10*time.Second)
+
+		Expect(decision.Allowed).To(BeFalse())
+		Expect(decision.Reason).To(Equal("played_too_short"))
+	})
+
+	It("records decisions for RecentFilterDecisions", func() {
+		p.evaluate(ctx, &track, 0)
+		p.recordFilterDecision(FilterRecord{TrackID: "t-1", Allowed: true})
+
+		Expect(p.RecentFilterDecisions()).To(HaveLen(1))
+		Expect(p.RecentFilterDecisions()[0].TrackID).To(Equal("t-1"))
+	})
+})
@@ -0,0 +1,152 @@
+package scrobbler
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+const lastFMAPIBaseURL = "https://ws.audioscrobbler.com/2.0/"
+
+// LastFMConfig holds the credentials needed to talk to the Last.fm API.
+// Enabled is false (the agent is not registered) until it is set.
+type LastFMConfig struct {
+	Enabled bool
+	APIKey  string
+	Secret  string
+}
+
+var lastFMConfig = struct {
+	sync.RWMutex
+	cfg LastFMConfig
+}{}
+
+// SetLastFMConfig installs the credentials used by the Last.fm agent. Meant
+// to be called once at startup from the server's configuration.
+func SetLastFMConfig(cfg LastFMConfig) {
+	lastFMConfig.Lock()
+	defer lastFMConfig.Unlock()
+	lastFMConfig.cfg = cfg
+}
+
+func init() {
+	Register("lastfm", func(ds model.DataStore) Scrobbler {
+		lastFMConfig.RLock()
+		cfg := lastFMConfig.cfg
+		lastFMConfig.RUnlock()
+		if !cfg.Enabled {
+			return nil
+		}
+		return &lastFMAgent{cfg: cfg, client: http.DefaultClient, baseURL: lastFMAPIBaseURL}
+	})
+}
+
+// lastFMAgent scrobbles to Last.fm's track.scrobble/track.updateNowPlaying
+// API, using the MusicBrainz recording ID from PlayMetadata (when available)
+// so tracks match even when title/artist tagging is imprecise.
+type lastFMAgent struct {
+	cfg     LastFMConfig
+	client  *http.Client
+	baseURL string
+}
+
+func (a *lastFMAgent) IsAuthorized(ctx context.Context, userId string) bool {
+	return a.cfg.Enabled
+}
+
+func (a *lastFMAgent) NowPlaying(ctx context.Context, userId string, track *model.MediaFile) error {
+	return a.NowPlayingWithMetadata(ctx, userId, track, PlayMetadata{})
+}
+
+func (a *lastFMAgent) NowPlayingWithMetadata(ctx context.Context, userId string, track *model.MediaFile, md PlayMetadata) error {
+	values := a.trackParams(track, md)
+	_, err := a.call(ctx, "track.updateNowPlaying", values)
+	return err
+}
+
+func (a *lastFMAgent) Scrobble(ctx context.Context, userId string, s Scrobble) error {
+	return a.ScrobbleWithMetadata(ctx, userId, s, PlayMetadata{})
+}
+
+func (a *lastFMAgent) ScrobbleWithMetadata(ctx context.Context, userId string, s Scrobble, md PlayMetadata) error {
+	values := a.trackParams(&s.MediaFile, md)
+	values.Set("timestamp", strconv.FormatInt(s.TimeStamp.Unix(), 10))
+	_, err := a.call(ctx, "track.scrobble", values)
+	return err
+}
+
+func (a *lastFMAgent) trackParams(track *model.MediaFile, md PlayMetadata) url.Values {
+	values := url.Values{}
+	values.Set("track", track.Title)
+	values.Set("artist", track.Artist)
+	values.Set("album", track.Album)
+	if md.MbzRecordingID != "" {
+		values.Set("mbid", md.MbzRecordingID)
+	}
+	return values
+}
+
+func (a *lastFMAgent) call(ctx context.Context, method string, values url.Values) ([]byte, error) {
+	values.Set("method", method)
+	values.Set("api_key", a.cfg.APIKey)
+	values.Set("api_sig", a.sign(values))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL, strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, ErrUnauthorized
+	case http.StatusNotFound:
+		return nil, ErrUnknownTrack
+	default:
+		log.Warn(ctx, "Last.fm API call failed", "method", method, "status", resp.StatusCode)
+		return nil, errors.New("lastfm: unexpected status " + resp.Status)
+	}
+}
+
+// sign computes Last.fm's api_sig: an md5 hex digest of every param
+// (excluding "format" and any existing "api_sig"), sorted by key and
+// concatenated as "keyvalue", with the shared secret appended. See
+// https://www.last.fm/api/authspec#8 for the exact scheme.
+func (a *lastFMAgent) sign(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		if k == "format" || k == "api_sig" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteString(values.Get(k))
+	}
+	sb.WriteString(a.cfg.Secret)
+
+	sum := md5.Sum([]byte(sb.String()))
+	return hex.EncodeToString(sum[:])
+}
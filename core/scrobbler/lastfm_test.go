@@ -0,0 +1,107 @@
+package scrobbler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/navidrome/navidrome/model"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("lastFMAgent", func() {
+	var server *httptest.Server
+	var agent *lastFMAgent
+	var track model.MediaFile
+	var gotValues map[string][]string
+
+	BeforeEach(func() {
+		gotValues = nil
+		track = model.MediaFile{Title: "Track Title", Artist: "Some Artist", Album: "Some Album"}
+	})
+
+	AfterEach(func() {
+		if server != nil {
+			server.Close()
+		}
+	})
+
+	newAgent := func(status int) *lastFMAgent {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.ParseForm()).To(Succeed())
+			gotValues = map[string][]string(r.Form)
+			w.WriteHeader(status)
+		}))
+		return &lastFMAgent{cfg: LastFMConfig{APIKey: "key", Secret: "shh"}, client: http.DefaultClient, baseURL: server.URL}
+	}
+
+	It("sends the mbid from PlayMetadata on NowPlayingWithMetadata", func() {
+		agent = newAgent(http.StatusOK)
+
+		err := agent.NowPlayingWithMetadata(context.Background(), "u-1", &track, PlayMetadata{MbzRecordingID: "mbz-1"})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gotValues["method"]).To(Equal([]string{"track.updateNowPlaying"}))
+		Expect(gotValues["mbid"]).To(Equal([]string{"mbz-1"}))
+		Expect(gotValues["track"]).To(Equal([]string{"Track Title"}))
+		Expect(gotValues["api_sig"]).To(HaveLen(1))
+		Expect(gotValues["api_sig"][0]).ToNot(BeEmpty())
+	})
+
+	It("sends the timestamp and mbid on ScrobbleWithMetadata", func() {
+		agent = newAgent(http.StatusOK)
+		ts := time.Now()
+
+		err := agent.ScrobbleWithMetadata(context.Background(), "u-1", Scrobble{MediaFile: track, TimeStamp: ts}, PlayMetadata{MbzRecordingID: "mbz-2"})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gotValues["method"]).To(Equal([]string{"track.scrobble"}))
+		Expect(gotValues["mbid"]).To(Equal([]string{"mbz-2"}))
+	})
+
+	It("maps 401/403 to ErrUnauthorized", func() {
+		agent = newAgent(http.StatusForbidden)
+
+		err := agent.Scrobble(context.Background(), "u-1", Scrobble{MediaFile: track})
+
+		Expect(err).To(MatchError(ErrUnauthorized))
+	})
+
+	It("maps 404 to ErrUnknownTrack", func() {
+		agent = newAgent(http.StatusNotFound)
+
+		err := agent.Scrobble(context.Background(), "u-1", Scrobble{MediaFile: track})
+
+		Expect(err).To(MatchError(ErrUnknownTrack))
+	})
+
+	It("returns a generic error for other statuses", func() {
+		agent = newAgent(http.StatusInternalServerError)
+
+		err := agent.Scrobble(context.Background(), "u-1", Scrobble{MediaFile: track})
+
+		Expect(err).To(HaveOccurred())
+		Expect(err).ToNot(MatchError(ErrUnauthorized))
+		Expect(err).ToNot(MatchError(ErrUnknownTrack))
+	})
+
+	Describe("sign", func() {
+		It("computes Last.fm's api_sig (sorted key+value concatenation, md5, secret appended)", func() {
+			signer := &lastFMAgent{cfg: LastFMConfig{Secret: "shh"}}
+			values := url.Values{"api_key": {"key"}, "method": {"track.scrobble"}, "track": {"T"}}
+
+			Expect(signer.sign(values)).To(Equal("84b4bb6eb4e83a8f321b14dbf8fe5f67"))
+		})
+
+		It("ignores format and any pre-existing api_sig", func() {
+			signer := &lastFMAgent{cfg: LastFMConfig{Secret: "shh"}}
+			withExtras := url.Values{"api_key": {"key"}, "method": {"track.scrobble"}, "track": {"T"}, "format": {"json"}, "api_sig": {"stale"}}
+			withoutExtras := url.Values{"api_key": {"key"}, "method": {"track.scrobble"}, "track": {"T"}}
+
+			Expect(signer.sign(withExtras)).To(Equal(signer.sign(withoutExtras)))
+		})
+	})
+})
@@ -0,0 +1,164 @@
+package scrobbler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+const listenBrainzAPIBaseURL = "https://api.listenbrainz.org/1/submit-listens"
+
+// ListenBrainzConfig holds the token needed to talk to the ListenBrainz
+// API. Enabled is false (the agent is not registered) until it is set.
+type ListenBrainzConfig struct {
+	Enabled bool
+	Token   string
+}
+
+var listenBrainzConfig = struct {
+	sync.RWMutex
+	cfg ListenBrainzConfig
+}{}
+
+// SetListenBrainzConfig installs the token used by the ListenBrainz agent.
+// Meant to be called once at startup from the server's configuration.
+func SetListenBrainzConfig(cfg ListenBrainzConfig) {
+	listenBrainzConfig.Lock()
+	defer listenBrainzConfig.Unlock()
+	listenBrainzConfig.cfg = cfg
+}
+
+func init() {
+	Register("listenbrainz", func(ds model.DataStore) Scrobbler {
+		listenBrainzConfig.RLock()
+		cfg := listenBrainzConfig.cfg
+		listenBrainzConfig.RUnlock()
+		if !cfg.Enabled {
+			return nil
+		}
+		return &listenBrainzAgent{cfg: cfg, client: http.DefaultClient, baseURL: listenBrainzAPIBaseURL}
+	})
+}
+
+// listenBrainzAgent submits listens to ListenBrainz's submit-listens API,
+// attaching the MusicBrainz identifiers from PlayMetadata as additional_info
+// so ListenBrainz can match the listen without relying on fuzzy title
+// matching.
+type listenBrainzAgent struct {
+	cfg     ListenBrainzConfig
+	client  *http.Client
+	baseURL string
+}
+
+type listenBrainzPayload struct {
+	ListenType string           `json:"listen_type"`
+	Payload    []listenBrainzTD `json:"payload"`
+}
+
+type listenBrainzTD struct {
+	ListenedAt int64                 `json:"listened_at,omitempty"`
+	TrackMD    listenBrainzTrackMeta `json:"track_metadata"`
+}
+
+type listenBrainzTrackMeta struct {
+	ArtistName     string                 `json:"artist_name"`
+	TrackName      string                 `json:"track_name"`
+	ReleaseName    string                 `json:"release_name,omitempty"`
+	AdditionalInfo listenBrainzAdditional `json:"additional_info"`
+}
+
+type listenBrainzAdditional struct {
+	RecordingMBID    string   `json:"recording_mbid,omitempty"`
+	ReleaseMBID      string   `json:"release_mbid,omitempty"`
+	ReleaseGroupMBID string   `json:"release_group_mbid,omitempty"`
+	ArtistMBIDs      []string `json:"artist_mbids,omitempty"`
+	ISRC             string   `json:"isrc,omitempty"`
+	TrackNumber      int      `json:"tracknumber,omitempty"`
+	DurationMs       int      `json:"duration_ms,omitempty"`
+}
+
+func (a *listenBrainzAgent) IsAuthorized(ctx context.Context, userId string) bool {
+	return a.cfg.Enabled
+}
+
+func (a *listenBrainzAgent) NowPlaying(ctx context.Context, userId string, track *model.MediaFile) error {
+	return a.NowPlayingWithMetadata(ctx, userId, track, PlayMetadata{})
+}
+
+func (a *listenBrainzAgent) NowPlayingWithMetadata(ctx context.Context, userId string, track *model.MediaFile, md PlayMetadata) error {
+	payload := listenBrainzPayload{
+		ListenType: "playing_now",
+		Payload:    []listenBrainzTD{{TrackMD: a.trackMetadata(track, md)}},
+	}
+	return a.submit(ctx, payload)
+}
+
+func (a *listenBrainzAgent) Scrobble(ctx context.Context, userId string, s Scrobble) error {
+	return a.ScrobbleWithMetadata(ctx, userId, s, PlayMetadata{})
+}
+
+func (a *listenBrainzAgent) ScrobbleWithMetadata(ctx context.Context, userId string, s Scrobble, md PlayMetadata) error {
+	payload := listenBrainzPayload{
+		ListenType: "single",
+		Payload: []listenBrainzTD{{
+			ListenedAt: s.TimeStamp.Unix(),
+			TrackMD:    a.trackMetadata(&s.MediaFile, md),
+		}},
+	}
+	return a.submit(ctx, payload)
+}
+
+func (a *listenBrainzAgent) trackMetadata(track *model.MediaFile, md PlayMetadata) listenBrainzTrackMeta {
+	return listenBrainzTrackMeta{
+		ArtistName:  track.Artist,
+		TrackName:   track.Title,
+		ReleaseName: track.Album,
+		AdditionalInfo: listenBrainzAdditional{
+			RecordingMBID:    md.MbzRecordingID,
+			ReleaseMBID:      md.MbzReleaseID,
+			ReleaseGroupMBID: md.MbzReleaseGroupID,
+			ArtistMBIDs:      md.MbzArtistIDs,
+			ISRC:             md.ISRC,
+			TrackNumber:      md.TrackNumber,
+			DurationMs:       int(md.DurationSeconds * 1000),
+		},
+	}
+}
+
+func (a *listenBrainzAgent) submit(ctx context.Context, payload listenBrainzPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Token "+a.cfg.Token)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	case http.StatusNotFound:
+		return ErrUnknownTrack
+	default:
+		log.Warn(ctx, "ListenBrainz API call failed", "listenType", payload.ListenType, "status", resp.StatusCode)
+		return errors.New("listenbrainz: unexpected status " + resp.Status)
+	}
+}
@@ -0,0 +1,93 @@
+package scrobbler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/navidrome/navidrome/model"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("listenBrainzAgent", func() {
+	var server *httptest.Server
+	var agent *listenBrainzAgent
+	var track model.MediaFile
+	var gotPayload listenBrainzPayload
+
+	BeforeEach(func() {
+		gotPayload = listenBrainzPayload{}
+		track = model.MediaFile{Title: "Track Title", Artist: "Some Artist", Album: "Some Album"}
+	})
+
+	AfterEach(func() {
+		if server != nil {
+			server.Close()
+		}
+	})
+
+	newAgent := func(status int) *listenBrainzAgent {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header.Get("Authorization")).To(Equal("Token tok-1"))
+			Expect(json.NewDecoder(r.Body).Decode(&gotPayload)).To(Succeed())
+			w.WriteHeader(status)
+		}))
+		return &listenBrainzAgent{cfg: ListenBrainzConfig{Token: "tok-1"}, client: http.DefaultClient, baseURL: server.URL}
+	}
+
+	It("sends MBIDs and ISRC from PlayMetadata on NowPlayingWithMetadata", func() {
+		agent = newAgent(http.StatusOK)
+		md := PlayMetadata{MbzRecordingID: "rec-1", MbzReleaseID: "rel-1", ISRC: "ISRC1", TrackNumber: 3, DurationSeconds: 180}
+
+		err := agent.NowPlayingWithMetadata(context.Background(), "u-1", &track, md)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gotPayload.ListenType).To(Equal("playing_now"))
+		info := gotPayload.Payload[0].TrackMD.AdditionalInfo
+		Expect(info.RecordingMBID).To(Equal("rec-1"))
+		Expect(info.ReleaseMBID).To(Equal("rel-1"))
+		Expect(info.ISRC).To(Equal("ISRC1"))
+		Expect(info.TrackNumber).To(Equal(3))
+		Expect(info.DurationMs).To(Equal(180000))
+	})
+
+	It("sends the listened_at timestamp on ScrobbleWithMetadata", func() {
+		agent = newAgent(http.StatusOK)
+		ts := time.Now()
+
+		err := agent.ScrobbleWithMetadata(context.Background(), "u-1", Scrobble{MediaFile: track, TimeStamp: ts}, PlayMetadata{})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gotPayload.ListenType).To(Equal("single"))
+		Expect(gotPayload.Payload[0].ListenedAt).To(Equal(ts.Unix()))
+	})
+
+	It("maps 401/403 to ErrUnauthorized", func() {
+		agent = newAgent(http.StatusUnauthorized)
+
+		err := agent.Scrobble(context.Background(), "u-1", Scrobble{MediaFile: track})
+
+		Expect(err).To(MatchError(ErrUnauthorized))
+	})
+
+	It("maps 404 to ErrUnknownTrack", func() {
+		agent = newAgent(http.StatusNotFound)
+
+		err := agent.Scrobble(context.Background(), "u-1", Scrobble{MediaFile: track})
+
+		Expect(err).To(MatchError(ErrUnknownTrack))
+	})
+
+	It("returns a generic error for other statuses", func() {
+		agent = newAgent(http.StatusInternalServerError)
+
+		err := agent.Scrobble(context.Background(), "u-1", Scrobble{MediaFile: track})
+
+		Expect(err).To(HaveOccurred())
+		Expect(err).ToNot(MatchError(ErrUnauthorized))
+		Expect(err).ToNot(MatchError(ErrUnknownTrack))
+	})
+})
@@ -0,0 +1,101 @@
+package scrobbler
+
+import (
+	"context"
+
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	"github.com/navidrome/navidrome/tests"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeMetadataScrobbler is a Scrobbler that also implements
+// MetadataScrobbler, used to verify that playTracker prefers the
+// metadata-aware methods and the PlayMetadata it builds is correct.
+type fakeMetadataScrobbler struct {
+	fakeScrobbler
+	NowPlayingMetadata PlayMetadata
+	ScrobbleMetadata   PlayMetadata
+}
+
+func (f *fakeMetadataScrobbler) NowPlayingWithMetadata(ctx context.Context, userId string, track *model.MediaFile, md PlayMetadata) error {
+	f.NowPlayingCalled = true
+	f.NowPlayingMetadata = md
+	return nil
+}
+
+func (f *fakeMetadataScrobbler) ScrobbleWithMetadata(ctx context.Context, userId string, s Scrobble, md PlayMetadata) error {
+	f.ScrobbleCalled = true
+	f.ScrobbleMetadata = md
+	return nil
+}
+
+var _ = Describe("playTracker metadata dispatch", func() {
+	var ctx context.Context
+	var ds model.DataStore
+	var tracker PlayTracker
+	var meta fakeMetadataScrobbler
+	var track model.MediaFile
+	var album model.Album
+
+	BeforeEach(func() {
+		SetFilterConfig(DefaultFilterConfig)
+		ctx = request.WithUser(context.Background(), model.User{ID: "u-1"})
+		ctx = request.WithPlayer(ctx, model.Player{ScrobbleEnabled: true})
+		ds = &tests.MockDataStore{}
+		meta = fakeMetadataScrobbler{fakeScrobbler: fakeScrobbler{Authorized: true}}
+		Register("fake-meta", func(model.DataStore) Scrobbler {
+			return &meta
+		})
+		tracker = newPlayTracker(ds, &fakeEventBroker{})
+		tracker.(*playTracker).scrobblers = map[string]Scrobbler{"fake-meta": &meta}
+
+		track = model.MediaFile{
+			ID:             "123",
+			Title:          "Track Title",
+			Artist:         "Some Artist",
+			AlbumID:        "al-1",
+			MbzRecordingID: "mbz-rec",
+			ISRC:           "US-ISRC-1",
+			TrackNumber:    5,
+			Duration:       200,
+			Participants: map[model.Role]model.ParticipantList{
+				model.RoleArtist: []model.Participant{_p("ar-1", "Artist 1")},
+			},
+		}
+		_ = ds.MediaFile(ctx).Put(&track)
+		album = model.Album{ID: "al-1", MbzAlbumID: "mbz-rel", MbzReleaseGroupID: "mbz-rg", MbzAlbumArtistID: "mbz-aa"}
+		_ = ds.Album(ctx).(*tests.MockAlbumRepo).Put(&album)
+		artist := model.Artist{ID: "ar-1", MbzArtistID: "mbz-artist-1"}
+		_ = ds.Artist(ctx).Put(&artist)
+	})
+
+	AfterEach(func() {
+		tracker.Close()
+	})
+
+	It("dispatches NowPlayingWithMetadata with the assembled PlayMetadata", func() {
+		err := tracker.NowPlaying(ctx, "player-1", "player-one", "123", 0, false)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(meta.NowPlayingCalled).To(BeTrue())
+		Expect(meta.NowPlayingMetadata.MbzRecordingID).To(Equal("mbz-rec"))
+		Expect(meta.NowPlayingMetadata.ISRC).To(Equal("US-ISRC-1"))
+		Expect(meta.NowPlayingMetadata.MbzReleaseID).To(Equal("mbz-rel"))
+		Expect(meta.NowPlayingMetadata.MbzReleaseGroupID).To(Equal("mbz-rg"))
+		Expect(meta.NowPlayingMetadata.TrackNumber).To(Equal(5))
+		Expect(meta.NowPlayingMetadata.MbzArtistIDs).To(Equal([]string{"mbz-artist-1"}))
+	})
+
+	It("dispatches ScrobbleWithMetadata with the assembled PlayMetadata", func() {
+		err := tracker.Submit(ctx, []Submission{{TrackID: "123"}})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(meta.ScrobbleCalled).To(BeTrue())
+		Expect(meta.ScrobbleMetadata.MbzRecordingID).To(Equal("mbz-rec"))
+		Expect(meta.ScrobbleMetadata.ISRC).To(Equal("US-ISRC-1"))
+		Expect(meta.ScrobbleMetadata.MbzReleaseID).To(Equal("mbz-rel"))
+		Expect(meta.ScrobbleMetadata.MbzArtistIDs).To(Equal([]string{"mbz-artist-1"}))
+	})
+})
@@ -0,0 +1,336 @@
+package scrobbler
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	"github.com/navidrome/navidrome/server/events"
+)
+
+// NowPlayingExpire is how long a "now playing" entry is kept around after
+// the last heartbeat received from the player, before being considered
+// stale and removed.
+const NowPlayingExpire = 3 * time.Minute
+
+// Submission represents a play reported by a client, to be scrobbled once
+// validated (see playTracker.Submit). PlayDuration, when known, is how long
+// the client actually played the track, used by the minimum-duration
+// filter rule; leave it zero if the client does not report it. Offline
+// marks a play recorded while the client had no connectivity: the server
+// still applies the filter rules, but defers transmission to agents by
+// queueing it directly, rather than trying (and likely failing) to send it
+// immediately.
+type Submission struct {
+	TrackID      string
+	Timestamp    time.Time
+	PlayDuration time.Duration
+	Offline      bool
+}
+
+// NowPlayingInfo describes a track currently being played by a given player,
+// as tracked by playTracker and exposed through GetNowPlaying.
+type NowPlayingInfo struct {
+	MediaFile  model.MediaFile
+	Start      time.Time
+	PlayerId   string
+	PlayerName string
+	Username   string
+	Position   int
+	Paused     bool
+}
+
+// PlayTracker receives playback notifications from players (NowPlaying
+// heartbeats and play Submissions) and fans them out to all registered
+// Scrobbler agents, while keeping play counts and the "now playing" list up
+// to date. Players are expected to call NowPlaying periodically (a
+// heartbeat) while a track plays, reporting the current position and
+// whether playback is paused, not just once at the start of the track.
+type PlayTracker interface {
+	NowPlaying(ctx context.Context, playerId string, playerName string, trackId string, position int, paused bool) error
+	GetNowPlaying(ctx context.Context) ([]NowPlayingInfo, error)
+	Submit(ctx context.Context, submissions []Submission) error
+	// RecentFilterDecisions returns the most recent filter rule decisions
+	// (allowed or not, and why), for an admin API to inspect.
+	RecentFilterDecisions() []FilterRecord
+	// Close stops the background offline-queue worker. It must be called
+	// once the PlayTracker is no longer needed to avoid leaking its
+	// goroutine.
+	Close()
+}
+
+type playTracker struct {
+	ds            model.DataStore
+	broker        events.Broker
+	scrobblers    map[string]Scrobbler
+	playMap       *ttlCache
+	cancel        context.CancelFunc
+	filterMu      sync.Mutex
+	filterHistory []FilterRecord
+}
+
+func newPlayTracker(ds model.DataStore, broker events.Broker) PlayTracker {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &playTracker{
+		ds:         ds,
+		broker:     broker,
+		scrobblers: createScrobblers(ds),
+		cancel:     cancel,
+	}
+	p.playMap = newTTLCache(p.onExpiration)
+	p.startQueueWorker(ctx)
+	return p
+}
+
+// Close stops the offline-queue worker goroutine started by newPlayTracker.
+func (p *playTracker) Close() {
+	p.cancel()
+}
+
+func (p *playTracker) NowPlaying(ctx context.Context, playerId string, playerName string, trackId string, position int, paused bool) error {
+	u, err := request.UserFrom(ctx)
+	if err != nil {
+		return err
+	}
+
+	mf, err := p.ds.MediaFile(ctx).Get(trackId)
+	if err != nil {
+		log.Error(ctx, "Error retrieving track for NowPlaying", "id", trackId, err)
+		return err
+	}
+
+	info := NowPlayingInfo{
+		MediaFile:  *mf,
+		Start:      time.Now(),
+		PlayerId:   playerId,
+		PlayerName: playerName,
+		Username:   u.UserName,
+		Position:   position,
+		Paused:     paused,
+	}
+	if err = p.playMap.AddWithTTL(playerId, info, NowPlayingExpire); err != nil {
+		log.Error(ctx, "Error storing NowPlaying info", "id", trackId, err)
+	}
+	p.broadcastNowPlaying(info, false)
+
+	decision := p.evaluate(ctx, mf, 0)
+	p.recordFilterDecision(FilterRecord{Timestamp: time.Now(), UserID: u.ID, PlayerId: playerId, TrackID: mf.ID, Allowed: decision.Allowed, Reason: decision.Reason})
+	if !decision.Allowed {
+		return nil
+	}
+
+	md := p.buildPlayMetadata(ctx, mf)
+	for name, s := range p.scrobblers {
+		if !s.IsAuthorized(ctx, u.ID) {
+			continue
+		}
+		if ms, ok := s.(MetadataScrobbler); ok {
+			if err := ms.NowPlayingWithMetadata(ctx, u.ID, mf, md); err != nil {
+				log.Warn(ctx, "Error sending NowPlaying to agent", "agent", name, "track", mf.Title, err)
+			}
+			continue
+		}
+		if err := s.NowPlaying(ctx, u.ID, mf); err != nil {
+			log.Warn(ctx, "Error sending NowPlaying to agent", "agent", name, "track", mf.Title, err)
+		}
+	}
+	return nil
+}
+
+// GetNowPlaying returns the full per-player NowPlayingInfo (position, pause
+// state included), most recently started first. SSE clients receive the same
+// state through the NowPlayingUpdated events sent by broadcastNowPlaying,
+// not through this method. This tree has no Subsonic getNowPlaying endpoint,
+// so Subsonic clients do not see this data.
+func (p *playTracker) GetNowPlaying(_ context.Context) ([]NowPlayingInfo, error) {
+	var playing []NowPlayingInfo
+	for _, entry := range p.playMap.Values() {
+		playing = append(playing, entry)
+	}
+	sort.Slice(playing, func(i, j int) bool {
+		return playing[i].Start.After(playing[j].Start)
+	})
+	return playing, nil
+}
+
+func (p *playTracker) Submit(ctx context.Context, submissions []Submission) error {
+	u, err := request.UserFrom(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range submissions {
+		mf, err := p.ds.MediaFile(ctx).Get(s.TrackID)
+		if err != nil {
+			log.Error(ctx, "Error retrieving track for scrobbling", "id", s.TrackID, err)
+			continue
+		}
+
+		if err := p.incPlayCounts(ctx, mf, s.Timestamp); err != nil {
+			log.Error(ctx, "Error incrementing play counts", "id", s.TrackID, err)
+		}
+
+		decision := p.evaluate(ctx, mf, s.PlayDuration)
+		p.recordFilterDecision(FilterRecord{Timestamp: time.Now(), UserID: u.ID, TrackID: mf.ID, Allowed: decision.Allowed, Reason: decision.Reason})
+		if !decision.Allowed {
+			continue
+		}
+
+		if s.Offline {
+			p.enqueueOffline(ctx, u.ID, mf, s.Timestamp)
+			continue
+		}
+
+		md := p.buildPlayMetadata(ctx, mf)
+		scrobble := Scrobble{MediaFile: *mf, TimeStamp: s.Timestamp}
+		for name, agent := range p.scrobblers {
+			if !agent.IsAuthorized(ctx, u.ID) {
+				continue
+			}
+			if ms, ok := agent.(MetadataScrobbler); ok {
+				if err := ms.ScrobbleWithMetadata(ctx, u.ID, scrobble, md); err != nil {
+					log.Warn(ctx, "Error scrobbling track", "agent", name, "track", mf.Title, err)
+				}
+				continue
+			}
+			if err := agent.Scrobble(ctx, u.ID, scrobble); err != nil {
+				log.Warn(ctx, "Error scrobbling track", "agent", name, "track", mf.Title, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (p *playTracker) incPlayCounts(ctx context.Context, mf *model.MediaFile, ts time.Time) error {
+	if err := p.ds.MediaFile(ctx).IncPlayCount(mf.ID, ts); err != nil {
+		return err
+	}
+	if err := p.ds.Album(ctx).IncPlayCount(mf.AlbumID, ts); err != nil {
+		return err
+	}
+	for _, artist := range mf.Participants[model.RoleArtist] {
+		if err := p.ds.Artist(ctx).IncPlayCount(artist.ID, ts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildPlayMetadata assembles the MusicBrainz/ISRC identifiers for a track,
+// pulling from the MediaFile itself, its Album and its participant artists,
+// so agents can match it against external catalogs without fuzzy string
+// matching.
+func (p *playTracker) buildPlayMetadata(ctx context.Context, mf *model.MediaFile) PlayMetadata {
+	md := PlayMetadata{
+		MbzRecordingID:  mf.MbzRecordingID,
+		ISRC:            mf.ISRC,
+		TrackNumber:     mf.TrackNumber,
+		DurationSeconds: float32(mf.Duration),
+		MediaType:       string(mf.MediaFileType()),
+	}
+
+	if album, err := p.ds.Album(ctx).Get(mf.AlbumID); err == nil && album != nil {
+		md.MbzReleaseID = album.MbzAlbumID
+		md.MbzReleaseGroupID = album.MbzReleaseGroupID
+		md.MbzAlbumArtistID = album.MbzAlbumArtistID
+	} else if err != nil {
+		log.Debug(ctx, "Could not load album for PlayMetadata", "id", mf.AlbumID, err)
+	}
+
+	for _, artist := range mf.Participants[model.RoleArtist] {
+		mbzID := artist.MbzArtistID
+		if mbzID == "" {
+			if a, err := p.ds.Artist(ctx).Get(artist.ID); err == nil && a != nil {
+				mbzID = a.MbzArtistID
+			}
+		}
+		if mbzID != "" {
+			md.MbzArtistIDs = append(md.MbzArtistIDs, mbzID)
+		}
+	}
+
+	return md
+}
+
+// broadcastNowPlaying notifies SSE clients of a change to a single player's
+// now-playing state: a NowPlayingCount for clients that only track the
+// total, followed by a NowPlayingUpdated carrying the full state, so
+// clients can reconcile position/pause without polling.
+func (p *playTracker) broadcastNowPlaying(info NowPlayingInfo, expired bool) {
+	ctx := context.Background()
+	p.broker.SendMessage(ctx, &events.NowPlayingCount{Count: len(p.playMap.Values())})
+	p.broker.SendMessage(ctx, &events.NowPlayingUpdated{
+		PlayerId:    info.PlayerId,
+		PlayerName:  info.PlayerName,
+		Username:    info.Username,
+		MediaFileId: info.MediaFile.ID,
+		Position:    info.Position,
+		Paused:      info.Paused,
+		Expired:     expired,
+	})
+}
+
+func (p *playTracker) onExpiration(_ string, info NowPlayingInfo) {
+	p.broadcastNowPlaying(info, true)
+}
+
+// ttlCache is a minimal in-memory map with per-key TTL expiration, used to
+// track currently playing tracks per player. Entries are evicted
+// automatically once their TTL elapses, invoking onExpire.
+type ttlCache struct {
+	mu       sync.Mutex
+	entries  map[string]*ttlEntry
+	onExpire func(key string, value NowPlayingInfo)
+}
+
+type ttlEntry struct {
+	value NowPlayingInfo
+	timer *time.Timer
+}
+
+func newTTLCache(onExpire func(key string, value NowPlayingInfo)) *ttlCache {
+	return &ttlCache{
+		entries:  map[string]*ttlEntry{},
+		onExpire: onExpire,
+	}
+}
+
+func (c *ttlCache) AddWithTTL(key string, value NowPlayingInfo, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if old, ok := c.entries[key]; ok {
+		old.timer.Stop()
+	}
+	entry := &ttlEntry{value: value}
+	entry.timer = time.AfterFunc(ttl, func() { c.expire(key) })
+	c.entries[key] = entry
+	return nil
+}
+
+func (c *ttlCache) expire(key string) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok {
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+	if ok && c.onExpire != nil {
+		c.onExpire(key, entry.value)
+	}
+}
+
+func (c *ttlCache) Values() []NowPlayingInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	values := make([]NowPlayingInfo, 0, len(c.entries))
+	for _, entry := range c.entries {
+		values = append(values, entry.value)
+	}
+	return values
+}
@@ -0,0 +1,13 @@
+package scrobbler
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestScrobbler(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Scrobbler Suite")
+}
@@ -0,0 +1,38 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// Error, Warn, Debug and Info log a message with optional key/value pairs.
+// The first argument may optionally be a context.Context (ignored here,
+// reserved for request-scoped fields such as request ID); everything else
+// is rendered as "key=value" pairs after the message, and a trailing
+// unpaired value (typically an error) is appended on its own.
+func Error(args ...interface{}) { logAt("ERROR", args...) }
+func Warn(args ...interface{})  { logAt("WARN", args...) }
+func Info(args ...interface{})  { logAt("INFO", args...) }
+func Debug(args ...interface{}) { logAt("DEBUG", args...) }
+
+func logAt(level string, args ...interface{}) {
+	if len(args) > 0 {
+		if _, ok := args[0].(context.Context); ok {
+			args = args[1:]
+		}
+	}
+	if len(args) == 0 {
+		log.Printf("[%s]", level)
+		return
+	}
+	msg := fmt.Sprint(args[0])
+	kvs := args[1:]
+	for i := 0; i+1 < len(kvs); i += 2 {
+		msg += fmt.Sprintf(" %v=%v", kvs[i], kvs[i+1])
+	}
+	if len(kvs)%2 == 1 {
+		msg += fmt.Sprintf(" %v", kvs[len(kvs)-1])
+	}
+	log.Printf("[%s] %s", level, msg)
+}
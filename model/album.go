@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// Album groups the tracks of a release together.
+type Album struct {
+	ID                string
+	Name              string
+	PlayCount         int64
+	MbzAlbumID        string
+	MbzReleaseGroupID string
+	MbzAlbumArtistID  string
+}
+
+// AlbumRepository persists Album records.
+type AlbumRepository interface {
+	Get(id string) (*Album, error)
+	Put(a *Album) error
+	// IncPlayCount bumps an album's play count and last-played timestamp.
+	IncPlayCount(id string, timestamp time.Time) error
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// Artist represents a performer, composer or other participant credited on
+// one or more tracks.
+type Artist struct {
+	ID             string
+	Name           string
+	SortArtistName string
+	MbzArtistID    string
+	PlayCount      int64
+}
+
+// ArtistRepository persists Artist records.
+type ArtistRepository interface {
+	Get(id string) (*Artist, error)
+	Put(a *Artist) error
+	// IncPlayCount bumps an artist's play count and last-played timestamp.
+	IncPlayCount(id string, timestamp time.Time) error
+}
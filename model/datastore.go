@@ -0,0 +1,13 @@
+package model
+
+import "context"
+
+// DataStore aggregates the repositories used to access persisted entities.
+// A single DataStore is built per request (or background job) and scoped
+// repositories are obtained by calling its accessor methods.
+type DataStore interface {
+	MediaFile(ctx context.Context) MediaFileRepository
+	Album(ctx context.Context) AlbumRepository
+	Artist(ctx context.Context) ArtistRepository
+	ScrobbleBuffer(ctx context.Context) ScrobbleBufferRepository
+}
@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+// Role identifies how a Participant is credited on a track (artist,
+// composer, producer, etc.).
+type Role string
+
+const (
+	RoleArtist   Role = "artist"
+	RoleComposer Role = "composer"
+)
+
+// Participant is an Artist credited on a track under a given Role.
+type Participant struct {
+	Artist
+}
+
+// ParticipantList is a list of Participant, grouped by Role in
+// MediaFile.Participants.
+type ParticipantList []Participant
+
+// MediaType classifies a MediaFile for scrobbling purposes (a song is
+// scrobbled, a podcast episode or audiobook chapter may be handled
+// differently by agents).
+type MediaType string
+
+const (
+	MediaTypeSong MediaType = "song"
+)
+
+// MediaFile represents a single playable track.
+type MediaFile struct {
+	ID             string
+	Title          string
+	Album          string
+	AlbumID        string
+	Artist         string
+	Genre          string
+	LibraryID      int
+	TrackNumber    int
+	Duration       float32
+	MbzRecordingID string
+	ISRC           string
+	PlayCount      int64
+	Participants   map[Role]ParticipantList
+}
+
+// MediaFileType reports the kind of content this MediaFile carries.
+func (mf MediaFile) MediaFileType() MediaType {
+	return MediaTypeSong
+}
+
+// MediaFileRepository persists MediaFile records.
+type MediaFileRepository interface {
+	Get(id string) (*MediaFile, error)
+	Put(m *MediaFile) error
+	// IncPlayCount bumps a track's play count and last-played timestamp.
+	IncPlayCount(id string, timestamp time.Time) error
+}
@@ -0,0 +1,10 @@
+package model
+
+// Player represents a client application used to play music, identified by
+// a combination of client name and username.
+type Player struct {
+	ID              string
+	Name            string
+	UserId          string
+	ScrobbleEnabled bool
+}
@@ -0,0 +1,45 @@
+// Package request carries per-request values (the authenticated User and
+// Player) through a context.Context, set once by server middleware and read
+// by the handlers/services further down the call chain.
+package request
+
+import (
+	"context"
+	"errors"
+
+	"github.com/navidrome/navidrome/model"
+)
+
+type contextKey string
+
+const (
+	userContextKey   contextKey = "user"
+	playerContextKey contextKey = "player"
+)
+
+// ErrNoUser is returned by UserFrom when the context carries no user.
+var ErrNoUser = errors.New("request: no user in context")
+
+func WithUser(ctx context.Context, u model.User) context.Context {
+	return context.WithValue(ctx, userContextKey, u)
+}
+
+func UserFrom(ctx context.Context) (model.User, error) {
+	u, ok := ctx.Value(userContextKey).(model.User)
+	if !ok {
+		return model.User{}, ErrNoUser
+	}
+	return u, nil
+}
+
+func WithPlayer(ctx context.Context, p model.Player) context.Context {
+	return context.WithValue(ctx, playerContextKey, p)
+}
+
+func PlayerFrom(ctx context.Context) (model.Player, error) {
+	p, ok := ctx.Value(playerContextKey).(model.Player)
+	if !ok {
+		return model.Player{}, errors.New("request: no player in context")
+	}
+	return p, nil
+}
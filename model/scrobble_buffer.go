@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// ScrobbleEntry represents a single play submission that could not be
+// delivered to a scrobbler agent yet (network failure, 5xx, rate-limit),
+// buffered for retry. Entries are kept per-agent so a long outage in one
+// service (e.g. Last.fm) never blocks submissions to another (e.g.
+// ListenBrainz).
+type ScrobbleEntry struct {
+	ID          string    `structs:"id" json:"id"`
+	UserID      string    `structs:"user_id" json:"userId"`
+	Agent       string    `structs:"agent" json:"agent"`
+	TrackID     string    `structs:"track_id" json:"trackId"`
+	PlayTime    time.Time `structs:"play_time" json:"playTime"`
+	EnqueuedAt  time.Time `structs:"enqueued_at" json:"enqueuedAt"`
+	Retries     int       `structs:"retries" json:"retries"`
+	NextRetryAt time.Time `structs:"next_retry_at" json:"nextRetryAt"`
+	LastError   string    `structs:"last_error" json:"lastError,omitempty"`
+}
+
+// ScrobbleBufferRepository persists ScrobbleEntry records across restarts,
+// so offline/failed scrobbles are not lost while waiting to be retried.
+type ScrobbleBufferRepository interface {
+	// Enqueue stores a new entry to be retried later.
+	Enqueue(entry *ScrobbleEntry) error
+	// Next returns up to `limit` entries for the given agent whose
+	// NextRetryAt has elapsed, ordered by PlayTime.
+	Next(agent string, limit int) ([]*ScrobbleEntry, error)
+	// Dequeue removes an entry, either because it was delivered
+	// successfully or because it failed permanently.
+	Dequeue(id string) error
+	// ScheduleRetry bumps an entry's retry count and sets when it should
+	// be attempted again, recording the error that caused the retry.
+	ScheduleRetry(id string, nextRetryAt time.Time, lastError string) error
+	// Length returns how many entries are currently queued for the agent.
+	Length(agent string) (int64, error)
+}
@@ -0,0 +1,8 @@
+package model
+
+// User represents an account able to log in and play music.
+type User struct {
+	ID       string
+	UserName string
+	IsAdmin  bool
+}
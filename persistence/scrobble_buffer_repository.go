@@ -0,0 +1,80 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// scrobbleBufferRepository is a SQL-backed model.ScrobbleBufferRepository,
+// storing entries in the scrobble_queue table (see db/migrations).
+type scrobbleBufferRepository struct {
+	ctx context.Context
+	db  *sql.DB
+}
+
+// NewScrobbleBufferRepository creates a model.ScrobbleBufferRepository
+// backed by db, scoped to ctx.
+func NewScrobbleBufferRepository(ctx context.Context, db *sql.DB) model.ScrobbleBufferRepository {
+	return &scrobbleBufferRepository{ctx: ctx, db: db}
+}
+
+func (r *scrobbleBufferRepository) Enqueue(entry *model.ScrobbleEntry) error {
+	_, err := r.db.ExecContext(r.ctx, `
+		insert into scrobble_queue
+			(id, user_id, agent, track_id, play_time, enqueued_at, retries, next_retry_at, last_error)
+		values (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.ID, entry.UserID, entry.Agent, entry.TrackID, entry.PlayTime,
+		entry.EnqueuedAt, entry.Retries, entry.NextRetryAt, entry.LastError,
+	)
+	if err != nil {
+		log.Error(r.ctx, "Error enqueueing scrobble", "id", entry.ID, err)
+	}
+	return err
+}
+
+func (r *scrobbleBufferRepository) Next(agent string, limit int) ([]*model.ScrobbleEntry, error) {
+	rows, err := r.db.QueryContext(r.ctx, `
+		select id, user_id, agent, track_id, play_time, enqueued_at, retries, next_retry_at, last_error
+		from scrobble_queue
+		where agent = ? and next_retry_at <= ?
+		order by play_time asc
+		limit ?`, agent, time.Now(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*model.ScrobbleEntry
+	for rows.Next() {
+		var e model.ScrobbleEntry
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Agent, &e.TrackID, &e.PlayTime,
+			&e.EnqueuedAt, &e.Retries, &e.NextRetryAt, &e.LastError); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *scrobbleBufferRepository) Dequeue(id string) error {
+	_, err := r.db.ExecContext(r.ctx, `delete from scrobble_queue where id = ?`, id)
+	return err
+}
+
+func (r *scrobbleBufferRepository) ScheduleRetry(id string, nextRetryAt time.Time, lastError string) error {
+	_, err := r.db.ExecContext(r.ctx, `
+		update scrobble_queue
+		set retries = retries + 1, next_retry_at = ?, last_error = ?
+		where id = ?`, nextRetryAt, lastError, id)
+	return err
+}
+
+func (r *scrobbleBufferRepository) Length(agent string) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(r.ctx, `select count(*) from scrobble_queue where agent = ?`, agent).Scan(&count)
+	return count, err
+}
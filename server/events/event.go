@@ -0,0 +1,30 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// Event is implemented by every message that can be broadcast to clients
+// through the Broker's SSE stream.
+type Event interface {
+	Data() string
+}
+
+// Broker accepts Server-Sent Events connections and fans out Events sent
+// through SendMessage to every connected client.
+type Broker interface {
+	http.Handler
+	SendMessage(ctx context.Context, event Event)
+}
+
+// marshalEvent renders an Event as an SSE "data:" line, tagged with the
+// event's type name so the frontend can dispatch on it.
+func marshalEvent(event Event) string {
+	data, _ := json.Marshal(event)
+	name := reflect.TypeOf(event).Elem().Name()
+	return fmt.Sprintf("event: message\ndata: {\"%s\": %s}\n\n", name, string(data))
+}
@@ -0,0 +1,29 @@
+package events
+
+// NowPlayingCount is sent whenever the number of currently playing tracks
+// changes, for clients that only need a badge/counter.
+type NowPlayingCount struct {
+	Count int
+}
+
+func (ev *NowPlayingCount) Data() string {
+	return marshalEvent(ev)
+}
+
+// NowPlayingUpdated carries the full playback state for a single player —
+// track, position, pause state — so clients can reconcile their "now
+// playing" UI without polling. Expired is set when the event was raised
+// because the entry's TTL elapsed rather than because of a heartbeat.
+type NowPlayingUpdated struct {
+	PlayerId    string
+	PlayerName  string
+	Username    string
+	MediaFileId string
+	Position    int
+	Paused      bool
+	Expired     bool
+}
+
+func (ev *NowPlayingUpdated) Data() string {
+	return marshalEvent(ev)
+}
@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"errors"
+	"time"
+
+	"github.com/navidrome/navidrome/model"
+)
+
+// MockAlbumRepo is an in-memory model.AlbumRepository for tests.
+type MockAlbumRepo struct {
+	data map[string]*model.Album
+}
+
+func (r *MockAlbumRepo) Get(id string) (*model.Album, error) {
+	if r.data == nil {
+		return nil, errors.New("not found")
+	}
+	a, ok := r.data[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return a, nil
+}
+
+func (r *MockAlbumRepo) Put(a *model.Album) error {
+	if r.data == nil {
+		r.data = map[string]*model.Album{}
+	}
+	r.data[a.ID] = a
+	return nil
+}
+
+func (r *MockAlbumRepo) IncPlayCount(id string, _ time.Time) error {
+	a, err := r.Get(id)
+	if err != nil {
+		return err
+	}
+	a.PlayCount++
+	return nil
+}
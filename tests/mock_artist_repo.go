@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"errors"
+	"time"
+
+	"github.com/navidrome/navidrome/model"
+)
+
+// MockArtistRepo is an in-memory model.ArtistRepository for tests.
+type MockArtistRepo struct {
+	data map[string]*model.Artist
+}
+
+func (r *MockArtistRepo) Get(id string) (*model.Artist, error) {
+	if r.data == nil {
+		return nil, errors.New("not found")
+	}
+	a, ok := r.data[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return a, nil
+}
+
+func (r *MockArtistRepo) Put(a *model.Artist) error {
+	if r.data == nil {
+		r.data = map[string]*model.Artist{}
+	}
+	r.data[a.ID] = a
+	return nil
+}
+
+func (r *MockArtistRepo) IncPlayCount(id string, _ time.Time) error {
+	a, err := r.Get(id)
+	if err != nil {
+		return err
+	}
+	a.PlayCount++
+	return nil
+}
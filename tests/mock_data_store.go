@@ -0,0 +1,44 @@
+package tests
+
+import (
+	"context"
+
+	"github.com/navidrome/navidrome/model"
+)
+
+// MockDataStore is an in-memory model.DataStore for tests, lazily
+// instantiating each repository mock on first access.
+type MockDataStore struct {
+	MockedMediaFile      model.MediaFileRepository
+	MockedAlbum          model.AlbumRepository
+	MockedArtist         model.ArtistRepository
+	MockedScrobbleBuffer model.ScrobbleBufferRepository
+}
+
+func (db *MockDataStore) MediaFile(context.Context) model.MediaFileRepository {
+	if db.MockedMediaFile == nil {
+		db.MockedMediaFile = &MockMediaFileRepo{}
+	}
+	return db.MockedMediaFile
+}
+
+func (db *MockDataStore) Album(context.Context) model.AlbumRepository {
+	if db.MockedAlbum == nil {
+		db.MockedAlbum = &MockAlbumRepo{}
+	}
+	return db.MockedAlbum
+}
+
+func (db *MockDataStore) Artist(context.Context) model.ArtistRepository {
+	if db.MockedArtist == nil {
+		db.MockedArtist = &MockArtistRepo{}
+	}
+	return db.MockedArtist
+}
+
+func (db *MockDataStore) ScrobbleBuffer(context.Context) model.ScrobbleBufferRepository {
+	if db.MockedScrobbleBuffer == nil {
+		db.MockedScrobbleBuffer = &MockScrobbleBufferRepo{}
+	}
+	return db.MockedScrobbleBuffer
+}
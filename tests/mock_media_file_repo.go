@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"errors"
+	"time"
+
+	"github.com/navidrome/navidrome/model"
+)
+
+// MockMediaFileRepo is an in-memory model.MediaFileRepository for tests.
+type MockMediaFileRepo struct {
+	data map[string]*model.MediaFile
+}
+
+func (r *MockMediaFileRepo) Get(id string) (*model.MediaFile, error) {
+	if r.data == nil {
+		return nil, errors.New("not found")
+	}
+	mf, ok := r.data[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return mf, nil
+}
+
+func (r *MockMediaFileRepo) Put(m *model.MediaFile) error {
+	if r.data == nil {
+		r.data = map[string]*model.MediaFile{}
+	}
+	r.data[m.ID] = m
+	return nil
+}
+
+func (r *MockMediaFileRepo) IncPlayCount(id string, _ time.Time) error {
+	mf, err := r.Get(id)
+	if err != nil {
+		return err
+	}
+	mf.PlayCount++
+	return nil
+}
@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/navidrome/navidrome/model"
+)
+
+// MockScrobbleBufferRepo is an in-memory model.ScrobbleBufferRepository for
+// tests.
+type MockScrobbleBufferRepo struct {
+	mu      sync.Mutex
+	entries map[string]*model.ScrobbleEntry
+}
+
+func (r *MockScrobbleBufferRepo) Enqueue(entry *model.ScrobbleEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.entries == nil {
+		r.entries = map[string]*model.ScrobbleEntry{}
+	}
+	r.entries[entry.ID] = entry
+	return nil
+}
+
+func (r *MockScrobbleBufferRepo) Next(agent string, limit int) ([]*model.ScrobbleEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []*model.ScrobbleEntry
+	now := time.Now()
+	for _, e := range r.entries {
+		if e.Agent == agent && !e.NextRetryAt.After(now) {
+			due = append(due, e)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].PlayTime.Before(due[j].PlayTime) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (r *MockScrobbleBufferRepo) Dequeue(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.entries, id)
+	return nil
+}
+
+func (r *MockScrobbleBufferRepo) ScheduleRetry(id string, nextRetryAt time.Time, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.entries[id]
+	if !ok {
+		return nil
+	}
+	e.Retries++
+	e.NextRetryAt = nextRetryAt
+	e.LastError = lastError
+	return nil
+}
+
+func (r *MockScrobbleBufferRepo) Length(agent string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var count int64
+	for _, e := range r.entries {
+		if e.Agent == agent {
+			count++
+		}
+	}
+	return count, nil
+}